@@ -0,0 +1,65 @@
+// backend.go defines the StorageBackend abstraction used to ship recordings
+// to one or more destinations, and the factory that builds the configured
+// set of backends from the application config.
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Backend names, as used in the STORAGE_BACKENDS config value.
+const (
+	backendS3         = "s3"
+	backendMinIO      = "minio"
+	backendFilesystem = "filesystem"
+	backendWebDAV     = "webdav"
+	backendAzureBlob  = "azureblob"
+)
+
+// StorageBackend is implemented by every destination recordings can be
+// shipped to. Implementations should be safe for concurrent use, since
+// checkAndUploadRecordings uploads to every configured backend in parallel.
+type StorageBackend interface {
+	// Name identifies the backend in logs and status annotations.
+	Name() string
+
+	// Upload ships the file at localPath to the backend under remoteKey,
+	// attaching metadata (e.g. encryption algorithm/recipient fingerprint)
+	// where the backend supports object metadata.
+	Upload(ctx context.Context, localPath, remoteKey string, metadata map[string]string) error
+
+	// Prune removes objects older than retention from the backend.
+	Prune(ctx context.Context, retention time.Duration) error
+}
+
+// newStorageBackends builds a StorageBackend for every name listed in
+// cfg.StorageBackends, in order.
+func newStorageBackends(cfg *Config) ([]StorageBackend, error) {
+	backends := make([]StorageBackend, 0, len(cfg.StorageBackends))
+
+	for _, name := range cfg.StorageBackends {
+		backend, err := newStorageBackend(cfg, name)
+		if err != nil {
+			return nil, wrapErr("configuring storage backend "+name, err)
+		}
+		backends = append(backends, backend)
+	}
+
+	return backends, nil
+}
+
+func newStorageBackend(cfg *Config, name string) (StorageBackend, error) {
+	switch name {
+	case backendS3, backendMinIO:
+		return newS3Backend(cfg, name)
+	case backendFilesystem:
+		return newFilesystemBackend(cfg)
+	case backendWebDAV:
+		return newWebDAVBackend(cfg)
+	case backendAzureBlob:
+		return newAzureBlobBackend(cfg)
+	default:
+		return nil, &UnknownBackendError{Backend: name}
+	}
+}
@@ -0,0 +1,79 @@
+// backend_azureblob.go implements the StorageBackend interface for
+// Azure Blob Storage.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+)
+
+// AzureBlobBackend uploads recordings to a container in Azure Blob Storage.
+type AzureBlobBackend struct {
+	containerURL azblob.ContainerURL
+}
+
+func newAzureBlobBackend(cfg *Config) (*AzureBlobBackend, error) {
+	credential, err := azblob.NewSharedKeyCredential(cfg.AzureStorageAccount, cfg.AzureStorageKey)
+	if err != nil {
+		return nil, wrapErr("creating Azure Blob credential", err)
+	}
+
+	serviceURL, err := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net", cfg.AzureStorageAccount))
+	if err != nil {
+		return nil, wrapErr("parsing Azure Blob Storage service URL", err)
+	}
+
+	pipeline := azblob.NewPipeline(credential, azblob.PipelineOptions{})
+	containerURL := azblob.NewServiceURL(*serviceURL, pipeline).NewContainerURL(cfg.AzureContainer)
+
+	return &AzureBlobBackend{containerURL: containerURL}, nil
+}
+
+func (b *AzureBlobBackend) Name() string {
+	return backendAzureBlob
+}
+
+func (b *AzureBlobBackend) Upload(ctx context.Context, localPath, remoteKey string, metadata map[string]string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return wrapErr("opening file for upload", err)
+	}
+	defer file.Close()
+
+	blobURL := b.containerURL.NewBlockBlobURL(remoteKey)
+	opts := azblob.UploadToBlockBlobOptions{Metadata: azblob.Metadata(metadata)}
+	if _, err := azblob.UploadFileToBlockBlob(ctx, file, blobURL, opts); err != nil {
+		return wrapErr("uploading file to Azure Blob Storage", err)
+	}
+
+	return nil
+}
+
+func (b *AzureBlobBackend) Prune(ctx context.Context, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+
+	for marker := (azblob.Marker{}); marker.NotDone(); {
+		resp, err := b.containerURL.ListBlobsFlatSegment(ctx, marker, azblob.ListBlobsSegmentOptions{})
+		if err != nil {
+			return wrapErr("listing Azure Blob Storage container", err)
+		}
+		marker = resp.NextMarker
+
+		for _, blob := range resp.Segment.BlobItems {
+			if blob.Properties.LastModified.After(cutoff) {
+				continue
+			}
+			if _, err := b.containerURL.NewBlobURL(blob.Name).Delete(
+				ctx, azblob.DeleteSnapshotsOptionNone, azblob.BlobAccessConditions{}); err != nil {
+				return wrapErr("deleting expired blob "+blob.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
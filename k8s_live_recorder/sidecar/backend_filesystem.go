@@ -0,0 +1,75 @@
+// backend_filesystem.go implements the StorageBackend interface for a local
+// filesystem or NFS-mounted destination directory.
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FilesystemBackend copies recordings into a directory, typically an
+// NFS or other shared-volume mount, for on-prem/air-gapped deployments
+// that cannot ship recordings to a cloud object store.
+type FilesystemBackend struct {
+	destDir string
+}
+
+func newFilesystemBackend(cfg *Config) (*FilesystemBackend, error) {
+	if err := os.MkdirAll(cfg.FilesystemDestDir, 0755); err != nil {
+		return nil, wrapErr("creating filesystem destination directory", err)
+	}
+
+	return &FilesystemBackend{destDir: cfg.FilesystemDestDir}, nil
+}
+
+func (b *FilesystemBackend) Name() string {
+	return backendFilesystem
+}
+
+// Upload copies localPath into the destination directory. metadata is
+// ignored: a plain filesystem has no object-metadata slot to put it in.
+func (b *FilesystemBackend) Upload(ctx context.Context, localPath, remoteKey string, metadata map[string]string) error {
+	destPath := filepath.Join(b.destDir, remoteKey)
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return wrapErr("creating destination directory", err)
+	}
+
+	src, err := os.Open(localPath)
+	if err != nil {
+		return wrapErr("opening file for copy", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return wrapErr("creating destination file", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return wrapErr("copying file to filesystem backend", err)
+	}
+
+	return nil
+}
+
+func (b *FilesystemBackend) Prune(ctx context.Context, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+
+	return filepath.Walk(b.destDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || info.ModTime().After(cutoff) {
+			return nil
+		}
+		if rmErr := os.Remove(path); rmErr != nil {
+			return wrapErr("removing expired recording "+path, rmErr)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,341 @@
+// backend_s3.go implements the StorageBackend interface for Amazon S3 and
+// for S3-compatible object stores such as MinIO.
+//
+// Uploads are driven through the low-level multipart API (rather than
+// s3manager.Uploader) because resuming an interrupted upload after the
+// sidecar restarts requires reusing the same UploadID and already-completed
+// part ETags, which s3manager does not expose. PartSize and Concurrency
+// still come from config, mirroring the knobs s3manager.Uploader offers.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// uploadStateSuffix names the JSON sidecar file that tracks an in-progress
+// multipart upload's UploadID and completed part ETags, keyed by backend
+// name since multiple S3-compatible backends can share one staged file.
+const uploadStateSuffix = ".upload-state.json"
+
+// S3Backend uploads recordings to Amazon S3 or to an S3-compatible endpoint
+// (MinIO, and similar). The two are distinguished only by name/config:
+// MinIO sets a custom endpoint and path-style addressing.
+type S3Backend struct {
+	name        string
+	bucket      string
+	client      *s3.S3
+	partSize    int64
+	concurrency int
+}
+
+func newS3Backend(cfg *Config, name string) (*S3Backend, error) {
+	awsCfg := &aws.Config{
+		Region: aws.String(cfg.S3Region),
+	}
+
+	// MinIO and other S3-compatible stores are reached through a custom
+	// endpoint and (almost always) need path-style addressing.
+	if name == backendMinIO || cfg.S3Endpoint != "" {
+		awsCfg.Endpoint = aws.String(cfg.S3Endpoint)
+		awsCfg.S3ForcePathStyle = aws.Bool(cfg.S3ForcePathStyle)
+	}
+
+	// Explicit credentials take priority when set (always the case for
+	// MinIO). Otherwise leave Credentials nil so the session falls back to
+	// the default provider chain, which includes IAM instance roles and
+	// IRSA (AWS_ROLE_ARN/AWS_WEB_IDENTITY_TOKEN_FILE).
+	if cfg.AWSAccessKeyID != "" {
+		awsCfg.Credentials = credentials.NewStaticCredentials(cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey, "")
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsCfg,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, wrapErr("creating AWS session", err)
+	}
+
+	return &S3Backend{
+		name:        name,
+		bucket:      cfg.S3BucketName,
+		client:      s3.New(sess),
+		partSize:    cfg.S3PartSizeBytes,
+		concurrency: cfg.S3UploadConcurrency,
+	}, nil
+}
+
+func (b *S3Backend) Name() string {
+	return b.name
+}
+
+// uploadState is the JSON sidecar persisted next to the local file being
+// uploaded, recording enough to resume the multipart upload after a
+// restart: the UploadID and the ETag of every part already accepted by S3.
+type uploadState struct {
+	Bucket         string               `json:"bucket"`
+	Key            string               `json:"key"`
+	UploadID       string               `json:"uploadId"`
+	PartSize       int64                `json:"partSize"`
+	CompletedParts []completedPartState `json:"completedParts"`
+}
+
+type completedPartState struct {
+	PartNumber int64  `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+func (b *S3Backend) Upload(ctx context.Context, localPath, remoteKey string, metadata map[string]string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return wrapErr("opening file for upload", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return wrapErr("stating file for upload", err)
+	}
+
+	statePath := localPath + "." + b.name + uploadStateSuffix
+	state, err := b.resumeOrCreateUpload(ctx, statePath, remoteKey, metadata)
+	if err != nil {
+		return err
+	}
+
+	completed := make(map[int64]string, len(state.CompletedParts))
+	for _, part := range state.CompletedParts {
+		completed[part.PartNumber] = part.ETag
+	}
+
+	totalParts := (info.Size() + state.PartSize - 1) / state.PartSize
+	if totalParts == 0 {
+		totalParts = 1 // S3 requires at least one (possibly empty) part
+	}
+
+	if err := b.uploadRemainingParts(ctx, file, info.Size(), statePath, state, completed, totalParts); err != nil {
+		// Deliberately leave the multipart upload and state sidecar in
+		// place (equivalent to s3manager's LeavePartsOnError): a later
+		// retry resumes from the parts already completed instead of
+		// re-uploading the whole file.
+		return wrapErr("uploading part to "+b.name, err)
+	}
+
+	if err := b.completeUpload(ctx, state, completed, totalParts); err != nil {
+		return wrapErr("completing multipart upload to "+b.name, err)
+	}
+
+	os.Remove(statePath)
+	return nil
+}
+
+func (b *S3Backend) resumeOrCreateUpload(ctx context.Context, statePath, remoteKey string, metadata map[string]string) (*uploadState, error) {
+	if data, err := os.ReadFile(statePath); err == nil {
+		var state uploadState
+		if err := json.Unmarshal(data, &state); err == nil && state.Bucket == b.bucket && state.Key == remoteKey {
+			log.Printf("Resuming multipart upload to %s/%s (%d part(s) already completed)", b.bucket, remoteKey, len(state.CompletedParts))
+			return &state, nil
+		}
+	}
+
+	out, err := b.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:   aws.String(b.bucket),
+		Key:      aws.String(remoteKey),
+		Metadata: aws.StringMap(metadata),
+	})
+	if err != nil {
+		return nil, wrapErr("creating multipart upload", err)
+	}
+
+	state := &uploadState{
+		Bucket:   b.bucket,
+		Key:      remoteKey,
+		UploadID: aws.StringValue(out.UploadId),
+		PartSize: b.partSize,
+	}
+	if err := writeUploadState(statePath, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// uploadRemainingParts uploads every part not already recorded in completed,
+// using up to b.concurrency workers, persisting state after each part lands
+// so progress survives a restart mid-upload.
+func (b *S3Backend) uploadRemainingParts(
+	ctx context.Context,
+	file *os.File,
+	fileSize int64,
+	statePath string,
+	state *uploadState,
+	completed map[int64]string,
+	totalParts int64,
+) error {
+	type partResult struct {
+		partNumber int64
+		etag       string
+		err        error
+	}
+
+	pending := make(chan int64)
+	results := make(chan partResult)
+
+	workers := b.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for partNumber := range pending {
+				etag, err := b.uploadPart(ctx, file, fileSize, state, partNumber)
+				results <- partResult{partNumber: partNumber, etag: etag, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pending)
+		for partNumber := int64(1); partNumber <= totalParts; partNumber++ {
+			if _, ok := completed[partNumber]; ok {
+				continue
+			}
+			select {
+			case pending <- partNumber:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	remaining := int64(0)
+	for partNumber := int64(1); partNumber <= totalParts; partNumber++ {
+		if _, ok := completed[partNumber]; !ok {
+			remaining++
+		}
+	}
+
+	var firstErr error
+	for i := int64(0); i < remaining; i++ {
+		select {
+		case res := <-results:
+			if res.err != nil {
+				if firstErr == nil {
+					firstErr = res.err
+				}
+				continue
+			}
+
+			completed[res.partNumber] = res.etag
+			state.CompletedParts = append(state.CompletedParts, completedPartState{PartNumber: res.partNumber, ETag: res.etag})
+			if err := writeUploadState(statePath, state); err != nil {
+				log.Printf("Warning: Failed to persist upload state for %s: %v", state.Key, err)
+			}
+		case <-ctx.Done():
+			// The feeder goroutine stops dispatching on cancellation, so
+			// fewer than `remaining` results will ever arrive. Return
+			// promptly instead of blocking forever on a result that's
+			// never coming.
+			if firstErr == nil {
+				firstErr = ctx.Err()
+			}
+			return firstErr
+		}
+	}
+
+	return firstErr
+}
+
+func (b *S3Backend) uploadPart(ctx context.Context, file *os.File, fileSize int64, state *uploadState, partNumber int64) (string, error) {
+	offset := (partNumber - 1) * state.PartSize
+	size := state.PartSize
+	if offset+size > fileSize {
+		size = fileSize - offset
+	}
+
+	buf := make([]byte, size)
+	if _, err := file.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return "", wrapErr("reading part from local file", err)
+	}
+
+	out, err := b.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(state.Bucket),
+		Key:        aws.String(state.Key),
+		UploadId:   aws.String(state.UploadID),
+		PartNumber: aws.Int64(partNumber),
+		Body:       bytes.NewReader(buf),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws.StringValue(out.ETag), nil
+}
+
+func (b *S3Backend) completeUpload(ctx context.Context, state *uploadState, completed map[int64]string, totalParts int64) error {
+	parts := make([]*s3.CompletedPart, 0, totalParts)
+	for partNumber := int64(1); partNumber <= totalParts; partNumber++ {
+		etag, ok := completed[partNumber]
+		if !ok {
+			return fmt.Errorf("finalizing multipart upload: missing completed part %d", partNumber)
+		}
+		parts = append(parts, &s3.CompletedPart{PartNumber: aws.Int64(partNumber), ETag: aws.String(etag)})
+	}
+
+	_, err := b.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(state.Bucket),
+		Key:             aws.String(state.Key),
+		UploadId:        aws.String(state.UploadID),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+func writeUploadState(statePath string, state *uploadState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return wrapErr("marshaling upload state", err)
+	}
+	if err := os.WriteFile(statePath, data, 0644); err != nil {
+		return wrapErr("writing upload state", err)
+	}
+	return nil
+}
+
+func (b *S3Backend) Prune(ctx context.Context, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+
+	var pruneErr error
+	err := b.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(b.bucket),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			if obj.LastModified == nil || obj.LastModified.After(cutoff) {
+				continue
+			}
+			if _, err := b.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+				Bucket: aws.String(b.bucket),
+				Key:    obj.Key,
+			}); err != nil {
+				pruneErr = wrapErr("deleting expired object from "+b.name, err)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return wrapErr("listing objects in "+b.name, err)
+	}
+
+	return pruneErr
+}
@@ -0,0 +1,87 @@
+// backend_webdav.go implements the StorageBackend interface for a WebDAV
+// server, another common on-prem/air-gapped storage target.
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"time"
+
+	"github.com/studio-b12/gowebdav"
+)
+
+// WebDAVBackend uploads recordings to a WebDAV server.
+type WebDAVBackend struct {
+	client *gowebdav.Client
+}
+
+func newWebDAVBackend(cfg *Config) (*WebDAVBackend, error) {
+	client := gowebdav.NewClient(cfg.WebDAVURL, cfg.WebDAVUsername, cfg.WebDAVPassword)
+
+	if err := client.Connect(); err != nil {
+		return nil, wrapErr("connecting to WebDAV server", err)
+	}
+
+	return &WebDAVBackend{client: client}, nil
+}
+
+func (b *WebDAVBackend) Name() string {
+	return backendWebDAV
+}
+
+// Upload streams localPath to the WebDAV server. metadata is ignored: plain
+// WebDAV has no object-metadata slot to put it in.
+func (b *WebDAVBackend) Upload(ctx context.Context, localPath, remoteKey string, metadata map[string]string) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return wrapErr("opening file for upload", err)
+	}
+	defer file.Close()
+
+	if err := b.client.MkdirAll(path.Dir(remoteKey), 0755); err != nil {
+		return wrapErr("creating WebDAV destination directory", err)
+	}
+
+	if err := b.client.WriteStream(remoteKey, file, 0644); err != nil {
+		return wrapErr("uploading file to WebDAV", err)
+	}
+
+	return nil
+}
+
+// Prune walks the WebDAV tree recursively, since uploads are written under
+// remoteKey subdirectories (e.g. "recordings/..." or "crash-recordings/..."),
+// not at the server root.
+func (b *WebDAVBackend) Prune(ctx context.Context, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+	return b.pruneDir(ctx, "/", cutoff)
+}
+
+func (b *WebDAVBackend) pruneDir(ctx context.Context, dir string, cutoff time.Time) error {
+	entries, err := b.client.ReadDir(dir)
+	if err != nil {
+		return wrapErr("listing WebDAV directory "+dir, err)
+	}
+
+	var pruneErr error
+	for _, entry := range entries {
+		entryPath := path.Join(dir, entry.Name())
+
+		if entry.IsDir() {
+			if err := b.pruneDir(ctx, entryPath, cutoff); err != nil {
+				pruneErr = err
+			}
+			continue
+		}
+
+		if entry.ModTime().After(cutoff) {
+			continue
+		}
+		if err := b.client.Remove(entryPath); err != nil {
+			pruneErr = wrapErr("removing expired recording "+entryPath, err)
+		}
+	}
+
+	return pruneErr
+}
@@ -2,7 +2,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,12 +17,69 @@ type Config struct {
 	// Target process related
 	AppProcessName string
 
-	// AWS S3 related
-	AWSAccessKeyID     string
-	AWSSecretAccessKey string
-	S3BucketName       string
-	S3Region           string
-	S3KeyPrefix        string
+	// Storage backend selection, e.g. "s3,webdav"
+	StorageBackends []string
+
+	// AWS S3 related. AWSAccessKeyID/AWSSecretAccessKey are optional for the
+	// "s3" backend: when unset, credentials come from the default provider
+	// chain (IAM role, IRSA via AWS_ROLE_ARN/AWS_WEB_IDENTITY_TOKEN_FILE,
+	// etc). MinIO has no equivalent, so it still requires explicit keys.
+	AWSAccessKeyID      string
+	AWSSecretAccessKey  string
+	S3BucketName        string
+	S3Region            string
+	S3KeyPrefix         string
+	S3Endpoint          string // custom endpoint for MinIO/S3-compatible stores
+	S3ForcePathStyle    bool   // required by most S3-compatible stores (e.g. MinIO)
+	S3PartSizeBytes     int64  // multipart upload part size
+	S3UploadConcurrency int    // concurrent part uploads per file
+
+	// Local disk retention related; a zero value disables that check.
+	MaxLocalRecordings int
+	MaxLocalBytes      int64
+
+	// Remote retention related; a zero RemoteRetention disables periodic
+	// pruning of uploaded recordings from the configured storage backends.
+	RemoteRetention              time.Duration
+	RemoteRetentionCheckInterval time.Duration
+
+	// Filesystem/NFS related
+	FilesystemDestDir string
+
+	// WebDAV related
+	WebDAVURL      string
+	WebDAVUsername string
+	WebDAVPassword string
+
+	// Azure Blob Storage related
+	AzureStorageAccount string
+	AzureStorageKey     string
+	AzureContainer      string
+
+	// Client-side encryption related
+	EncryptionMode             string // "none", "aes", or "pgp"
+	EncryptionPassphrase       string
+	EncryptionPGPRecipientsDir string
+
+	// Scheduled/auto-recording related
+	AutoRecordSchedule string // standard cron expression; empty disables auto-recording
+	AutoRecordDuration time.Duration
+	AutoRecordOnCrash  bool
+	CrashRetainCount   int
+	CrashS3KeyPrefix   string
+
+	// Notification related, e.g. "slack,email"; empty disables notifications
+	NotifyChannels      []string
+	NotifyTemplatesDir  string // optional ConfigMap mount overriding the embedded default templates
+	SlackWebhookURL     string
+	NotifyWebhookURL    string
+	SMTPHost            string
+	SMTPPort            string
+	SMTPUsername        string
+	SMTPPassword        string
+	SMTPFrom            string
+	SMTPTo              string
+	PagerDutyRoutingKey string
 }
 
 // Environment variables
@@ -26,56 +87,316 @@ const (
 	namespaceEnv       = "POD_NAMESPACE"
 	podNameEnv         = "POD_NAME"
 	appProcessNameEnv  = "APP_PROCESS_NAME"
-	awsAccessKeyIDEnv  = "AWS_ACCESS_KEY_ID"
-	awsSecretAccessEnv = "AWS_SECRET_ACCESS_KEY"
-	s3BucketNameEnv    = "S3_BUCKET_NAME"
-	s3RegionEnv        = "S3_REGION"
-	s3KeyPrefixEnv     = "S3_KEY_PREFIX"
+	storageBackendsEnv = "STORAGE_BACKENDS"
+
+	awsAccessKeyIDEnv      = "AWS_ACCESS_KEY_ID"
+	awsSecretAccessEnv     = "AWS_SECRET_ACCESS_KEY"
+	s3BucketNameEnv        = "S3_BUCKET_NAME"
+	s3RegionEnv            = "S3_REGION"
+	s3KeyPrefixEnv         = "S3_KEY_PREFIX"
+	s3EndpointEnv          = "S3_ENDPOINT"
+	s3ForcePathStyleEnv    = "S3_FORCE_PATH_STYLE"
+	s3PartSizeBytesEnv     = "S3_PART_SIZE_BYTES"
+	s3UploadConcurrencyEnv = "S3_UPLOAD_CONCURRENCY"
+
+	maxLocalRecordingsEnv = "MAX_LOCAL_RECORDINGS"
+	maxLocalBytesEnv      = "MAX_LOCAL_BYTES"
+
+	remoteRetentionEnv              = "REMOTE_RETENTION"
+	remoteRetentionCheckIntervalEnv = "REMOTE_RETENTION_CHECK_INTERVAL"
+
+	filesystemDestDirEnv = "FILESYSTEM_DEST_DIR"
+
+	webdavURLEnv      = "WEBDAV_URL"
+	webdavUsernameEnv = "WEBDAV_USERNAME"
+	webdavPasswordEnv = "WEBDAV_PASSWORD"
+
+	azureStorageAccountEnv = "AZURE_STORAGE_ACCOUNT"
+	azureStorageKeyEnv     = "AZURE_STORAGE_KEY"
+	azureContainerEnv      = "AZURE_CONTAINER"
+
+	encryptionModeEnv             = "ENCRYPTION_MODE"
+	encryptionPassphraseEnv       = "ENCRYPTION_PASSPHRASE"
+	encryptionPGPRecipientsDirEnv = "ENCRYPTION_PGP_RECIPIENTS_DIR"
+
+	autoRecordScheduleEnv = "AUTO_RECORD_SCHEDULE"
+	autoRecordDurationEnv = "AUTO_RECORD_DURATION"
+	autoRecordOnCrashEnv  = "AUTO_RECORD_ON_CRASH"
+	crashRetainCountEnv   = "CRASH_RETAIN_COUNT"
+	crashS3KeyPrefixEnv   = "CRASH_S3_KEY_PREFIX"
+
+	notifyChannelsEnv      = "NOTIFY_CHANNELS"
+	notifyTemplatesDirEnv  = "NOTIFY_TEMPLATES_DIR"
+	slackWebhookURLEnv     = "SLACK_WEBHOOK_URL"
+	notifyWebhookURLEnv    = "NOTIFY_WEBHOOK_URL"
+	smtpHostEnv            = "SMTP_HOST"
+	smtpPortEnv            = "SMTP_PORT"
+	smtpUsernameEnv        = "SMTP_USERNAME"
+	smtpPasswordEnv        = "SMTP_PASSWORD"
+	smtpFromEnv            = "SMTP_FROM"
+	smtpToEnv              = "SMTP_TO"
+	pagerDutyRoutingKeyEnv = "PAGERDUTY_ROUTING_KEY"
 )
 
 // Defaults
 const (
-	defaultNamespace   = "default"
-	defaultS3Region    = "us-east-1"
-	defaultS3KeyPrefix = "recordings"
+	defaultNamespace          = "default"
+	defaultS3Region           = "us-east-1"
+	defaultS3KeyPrefix        = "recordings"
+	defaultStorageBackends    = "s3"
+	defaultFilesystemDestDir  = "/recordings-offsite"
+	defaultEncryptionMode     = encryptionModeNone
+	defaultAutoRecordDuration = 5 * time.Minute
+	defaultCrashRetainCount   = 3
+	defaultCrashS3KeyPrefix   = "crash-recordings"
+	defaultSMTPPort           = "587"
+
+	defaultS3PartSizeBytes     = 8 * 1024 * 1024 // 8MB, above the 5MB S3 minimum
+	defaultS3UploadConcurrency = 4
+
+	defaultRemoteRetentionCheckInterval = 1 * time.Hour
 )
 
 func loadConfig() (*Config, error) {
 	cfg := &Config{
-		Namespace:          getEnvOrDefault(namespaceEnv, defaultNamespace),
-		PodName:            os.Getenv(podNameEnv),
-		AppProcessName:     os.Getenv(appProcessNameEnv),
-		AWSAccessKeyID:     os.Getenv(awsAccessKeyIDEnv),
-		AWSSecretAccessKey: os.Getenv(awsSecretAccessEnv),
-		S3BucketName:       os.Getenv(s3BucketNameEnv),
-		S3Region:           getEnvOrDefault(s3RegionEnv, defaultS3Region),
-		S3KeyPrefix:        getEnvOrDefault(s3KeyPrefixEnv, defaultS3KeyPrefix),
+		Namespace:           getEnvOrDefault(namespaceEnv, defaultNamespace),
+		PodName:             os.Getenv(podNameEnv),
+		AppProcessName:      os.Getenv(appProcessNameEnv),
+		StorageBackends:     parseStorageBackends(getEnvOrDefault(storageBackendsEnv, defaultStorageBackends)),
+		AWSAccessKeyID:      os.Getenv(awsAccessKeyIDEnv),
+		AWSSecretAccessKey:  os.Getenv(awsSecretAccessEnv),
+		S3BucketName:        os.Getenv(s3BucketNameEnv),
+		S3Region:            getEnvOrDefault(s3RegionEnv, defaultS3Region),
+		S3KeyPrefix:         getEnvOrDefault(s3KeyPrefixEnv, defaultS3KeyPrefix),
+		S3Endpoint:          os.Getenv(s3EndpointEnv),
+		S3ForcePathStyle:    os.Getenv(s3ForcePathStyleEnv) == "true",
+		FilesystemDestDir:   getEnvOrDefault(filesystemDestDirEnv, defaultFilesystemDestDir),
+		WebDAVURL:           os.Getenv(webdavURLEnv),
+		WebDAVUsername:      os.Getenv(webdavUsernameEnv),
+		WebDAVPassword:      os.Getenv(webdavPasswordEnv),
+		AzureStorageAccount: os.Getenv(azureStorageAccountEnv),
+		AzureStorageKey:     os.Getenv(azureStorageKeyEnv),
+		AzureContainer:      os.Getenv(azureContainerEnv),
+
+		EncryptionMode:             getEnvOrDefault(encryptionModeEnv, defaultEncryptionMode),
+		EncryptionPassphrase:       os.Getenv(encryptionPassphraseEnv),
+		EncryptionPGPRecipientsDir: os.Getenv(encryptionPGPRecipientsDirEnv),
+
+		AutoRecordSchedule: os.Getenv(autoRecordScheduleEnv),
+		AutoRecordOnCrash:  os.Getenv(autoRecordOnCrashEnv) == "true",
+		CrashS3KeyPrefix:   getEnvOrDefault(crashS3KeyPrefixEnv, defaultCrashS3KeyPrefix),
+
+		NotifyChannels:      parseNotifyChannels(os.Getenv(notifyChannelsEnv)),
+		NotifyTemplatesDir:  os.Getenv(notifyTemplatesDirEnv),
+		SlackWebhookURL:     os.Getenv(slackWebhookURLEnv),
+		NotifyWebhookURL:    os.Getenv(notifyWebhookURLEnv),
+		SMTPHost:            os.Getenv(smtpHostEnv),
+		SMTPPort:            getEnvOrDefault(smtpPortEnv, defaultSMTPPort),
+		SMTPUsername:        os.Getenv(smtpUsernameEnv),
+		SMTPPassword:        os.Getenv(smtpPasswordEnv),
+		SMTPFrom:            os.Getenv(smtpFromEnv),
+		SMTPTo:              os.Getenv(smtpToEnv),
+		PagerDutyRoutingKey: os.Getenv(pagerDutyRoutingKeyEnv),
+	}
+
+	autoRecordDuration, err := parseDurationOrDefault(autoRecordDurationEnv, defaultAutoRecordDuration)
+	if err != nil {
+		return nil, wrapErr("validating auto-record configuration", err)
+	}
+	cfg.AutoRecordDuration = autoRecordDuration
+
+	crashRetainCount, err := parseIntOrDefault(crashRetainCountEnv, defaultCrashRetainCount)
+	if err != nil {
+		return nil, wrapErr("validating crash-handling configuration", err)
+	}
+	cfg.CrashRetainCount = crashRetainCount
+
+	s3PartSizeBytes, err := parseInt64OrDefault(s3PartSizeBytesEnv, defaultS3PartSizeBytes)
+	if err != nil {
+		return nil, wrapErr("validating S3 upload configuration", err)
+	}
+	cfg.S3PartSizeBytes = s3PartSizeBytes
+
+	s3UploadConcurrency, err := parseIntOrDefault(s3UploadConcurrencyEnv, defaultS3UploadConcurrency)
+	if err != nil {
+		return nil, wrapErr("validating S3 upload configuration", err)
+	}
+	cfg.S3UploadConcurrency = s3UploadConcurrency
+
+	maxLocalRecordings, err := parseIntOrDefault(maxLocalRecordingsEnv, 0)
+	if err != nil {
+		return nil, wrapErr("validating local retention configuration", err)
+	}
+	cfg.MaxLocalRecordings = maxLocalRecordings
+
+	maxLocalBytes, err := parseInt64OrDefault(maxLocalBytesEnv, 0)
+	if err != nil {
+		return nil, wrapErr("validating local retention configuration", err)
+	}
+	cfg.MaxLocalBytes = maxLocalBytes
+
+	remoteRetention, err := parseDurationOrDefault(remoteRetentionEnv, 0)
+	if err != nil {
+		return nil, wrapErr("validating remote retention configuration", err)
+	}
+	cfg.RemoteRetention = remoteRetention
+
+	remoteRetentionCheckInterval, err := parseDurationOrDefault(remoteRetentionCheckIntervalEnv, defaultRemoteRetentionCheckInterval)
+	if err != nil {
+		return nil, wrapErr("validating remote retention configuration", err)
 	}
+	cfg.RemoteRetentionCheckInterval = remoteRetentionCheckInterval
 
 	if cfg.PodName == "" {
 		return nil, wrapErr("validating configuration",
 			&MissingEnvError{EnvVar: podNameEnv})
 	}
 
-	// AWS S3 credentials are now required for the app to start
-	if cfg.S3BucketName == "" {
-		return nil, wrapErr("validating S3 configuration",
-			&MissingEnvError{EnvVar: s3BucketNameEnv})
+	if len(cfg.StorageBackends) == 0 {
+		return nil, wrapErr("validating configuration",
+			&MissingEnvError{EnvVar: storageBackendsEnv})
+	}
+
+	for _, backend := range cfg.StorageBackends {
+		if err := validateBackendConfig(cfg, backend); err != nil {
+			return nil, err
+		}
 	}
 
-	if cfg.AWSAccessKeyID == "" {
-		return nil, wrapErr("validating AWS credentials",
-			&MissingEnvError{EnvVar: awsAccessKeyIDEnv})
+	if err := validateEncryptionConfig(cfg); err != nil {
+		return nil, err
 	}
 
-	if cfg.AWSSecretAccessKey == "" {
-		return nil, wrapErr("validating AWS credentials",
-			&MissingEnvError{EnvVar: awsSecretAccessEnv})
+	for _, channel := range cfg.NotifyChannels {
+		if err := validateNotifyConfig(cfg, channel); err != nil {
+			return nil, err
+		}
 	}
 
 	return cfg, nil
 }
 
+// validateEncryptionConfig checks that the environment variables required by
+// the configured encryption mode are present.
+func validateEncryptionConfig(cfg *Config) error {
+	switch cfg.EncryptionMode {
+	case encryptionModeNone:
+		return nil
+	case encryptionModeAES:
+		if cfg.EncryptionPassphrase == "" {
+			return wrapErr("validating encryption configuration", &MissingEnvError{EnvVar: encryptionPassphraseEnv})
+		}
+	case encryptionModePGP:
+		if cfg.EncryptionPGPRecipientsDir == "" {
+			return wrapErr("validating encryption configuration", &MissingEnvError{EnvVar: encryptionPGPRecipientsDirEnv})
+		}
+	default:
+		return wrapErr("validating encryption configuration", fmt.Errorf("unknown encryption mode: %s", cfg.EncryptionMode))
+	}
+	return nil
+}
+
+// validateBackendConfig checks that the environment variables required by a
+// single configured backend are present.
+func validateBackendConfig(cfg *Config, backend string) error {
+	switch backend {
+	case backendS3, backendMinIO:
+		if cfg.S3BucketName == "" {
+			return wrapErr("validating S3 configuration", &MissingEnvError{EnvVar: s3BucketNameEnv})
+		}
+		// MinIO has no IAM/IRSA equivalent, so it always needs explicit
+		// credentials. Plain S3 can fall back to the default credential
+		// chain (IAM role, IRSA), so AWSAccessKeyID/AWSSecretAccessKey are
+		// optional there.
+		if backend == backendMinIO {
+			if cfg.AWSAccessKeyID == "" {
+				return wrapErr("validating AWS credentials", &MissingEnvError{EnvVar: awsAccessKeyIDEnv})
+			}
+			if cfg.AWSSecretAccessKey == "" {
+				return wrapErr("validating AWS credentials", &MissingEnvError{EnvVar: awsSecretAccessEnv})
+			}
+			if cfg.S3Endpoint == "" {
+				return wrapErr("validating MinIO configuration", &MissingEnvError{EnvVar: s3EndpointEnv})
+			}
+		}
+	case backendFilesystem:
+		if cfg.FilesystemDestDir == "" {
+			return wrapErr("validating filesystem configuration", &MissingEnvError{EnvVar: filesystemDestDirEnv})
+		}
+	case backendWebDAV:
+		if cfg.WebDAVURL == "" {
+			return wrapErr("validating WebDAV configuration", &MissingEnvError{EnvVar: webdavURLEnv})
+		}
+	case backendAzureBlob:
+		if cfg.AzureStorageAccount == "" {
+			return wrapErr("validating Azure Blob configuration", &MissingEnvError{EnvVar: azureStorageAccountEnv})
+		}
+		if cfg.AzureStorageKey == "" {
+			return wrapErr("validating Azure Blob configuration", &MissingEnvError{EnvVar: azureStorageKeyEnv})
+		}
+		if cfg.AzureContainer == "" {
+			return wrapErr("validating Azure Blob configuration", &MissingEnvError{EnvVar: azureContainerEnv})
+		}
+	default:
+		return wrapErr("validating configuration", &UnknownBackendError{Backend: backend})
+	}
+	return nil
+}
+
+// validateNotifyConfig checks that the environment variables required by a
+// single configured notification channel are present.
+func validateNotifyConfig(cfg *Config, channel string) error {
+	switch channel {
+	case notifyChannelSlack:
+		if cfg.SlackWebhookURL == "" {
+			return wrapErr("validating Slack notification configuration", &MissingEnvError{EnvVar: slackWebhookURLEnv})
+		}
+	case notifyChannelWebhook:
+		if cfg.NotifyWebhookURL == "" {
+			return wrapErr("validating webhook notification configuration", &MissingEnvError{EnvVar: notifyWebhookURLEnv})
+		}
+	case notifyChannelEmail:
+		if cfg.SMTPHost == "" {
+			return wrapErr("validating email notification configuration", &MissingEnvError{EnvVar: smtpHostEnv})
+		}
+		if cfg.SMTPFrom == "" {
+			return wrapErr("validating email notification configuration", &MissingEnvError{EnvVar: smtpFromEnv})
+		}
+		if cfg.SMTPTo == "" {
+			return wrapErr("validating email notification configuration", &MissingEnvError{EnvVar: smtpToEnv})
+		}
+	case notifyChannelPagerDuty:
+		if cfg.PagerDutyRoutingKey == "" {
+			return wrapErr("validating PagerDuty notification configuration", &MissingEnvError{EnvVar: pagerDutyRoutingKeyEnv})
+		}
+	default:
+		return wrapErr("validating configuration", &UnknownNotifyChannelError{Channel: channel})
+	}
+	return nil
+}
+
+func parseNotifyChannels(raw string) []string {
+	var channels []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			channels = append(channels, name)
+		}
+	}
+	return channels
+}
+
+func parseStorageBackends(raw string) []string {
+	var backends []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			backends = append(backends, name)
+		}
+	}
+	return backends
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -83,6 +404,42 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+func parseDurationOrDefault(key string, defaultValue time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, wrapErr("parsing "+key, err)
+	}
+	return duration, nil
+}
+
+func parseIntOrDefault(key string, defaultValue int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, wrapErr("parsing "+key, err)
+	}
+	return value, nil
+}
+
+func parseInt64OrDefault(key string, defaultValue int64) (int64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, wrapErr("parsing "+key, err)
+	}
+	return value, nil
+}
+
 type MissingEnvError struct {
 	EnvVar string
 }
@@ -90,3 +447,19 @@ type MissingEnvError struct {
 func (e *MissingEnvError) Error() string {
 	return "missing required environment variable: " + e.EnvVar
 }
+
+type UnknownBackendError struct {
+	Backend string
+}
+
+func (e *UnknownBackendError) Error() string {
+	return "unknown storage backend: " + e.Backend
+}
+
+type UnknownNotifyChannelError struct {
+	Channel string
+}
+
+func (e *UnknownNotifyChannelError) Error() string {
+	return "unknown notification channel: " + e.Channel
+}
@@ -0,0 +1,120 @@
+// crashwatch.go implements AUTO_RECORD_ON_CRASH: watching the target process
+// and, if it disappears unexpectedly, preserving the most recent recordings
+// under a distinct S3 prefix instead of letting them get cleaned up as
+// routine traffic.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const crashPollInterval = 1 * time.Second
+
+// crashShutdownGracePeriod is how long watchForTargetCrash waits, after
+// noticing the target process has disappeared, to see whether the
+// sidecar's own context is also being cancelled. Kubernetes delivers
+// SIGTERM to every container in a pod at roughly the same time, so a
+// target exit that coincides with our own shutdown signal is the pod
+// terminating normally, not a crash.
+const crashShutdownGracePeriod = 2 * time.Second
+
+// crashMarkerSuffix is appended to a recording file to mark it as
+// crash-related; the uploader checks for this marker to decide which S3
+// prefix to use. The "crash" status annotation is set by handleCrash.
+const crashMarkerSuffix = ".crash"
+
+// watchForTargetCrash polls targetPID and, once it disappears, treats that
+// as a crash: it stops any in-flight recording and marks the most recent
+// CrashRetainCount recordings for upload under CrashS3KeyPrefix. It returns
+// once a crash has been handled, or when ctx is cancelled.
+func (rc *RecorderController) watchForTargetCrash(ctx context.Context, targetPID int) {
+	ticker := time.NewTicker(crashPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if processAlive(targetPID) {
+				continue
+			}
+			if shuttingDown(ctx) {
+				log.Printf("Target process %d exited during pod shutdown, not treating it as a crash", targetPID)
+				return
+			}
+			log.Printf("Target process %d is no longer running, treating it as a crash", targetPID)
+			rc.handleCrash(ctx)
+			return
+		}
+	}
+}
+
+// processAlive only checks whether /proc/<pid> still exists, so on its own
+// it can't distinguish a crash from a clean, intentional exit: this sidecar
+// isn't the target's parent, so it has no way to wait(2) for an exit status.
+// shuttingDown covers the one case that matters operationally - the whole
+// pod terminating - by checking whether our own context is also being
+// cancelled around the same time; an app restart that isn't part of a pod
+// shutdown still reads as a crash.
+func processAlive(pid int) bool {
+	_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(pid)))
+	return err == nil
+}
+
+// shuttingDown reports whether ctx is already cancelled, or becomes
+// cancelled within crashShutdownGracePeriod.
+func shuttingDown(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(crashShutdownGracePeriod):
+		return false
+	}
+}
+
+func (rc *RecorderController) handleCrash(ctx context.Context) {
+	rc.recordingLock.Lock()
+	rc.stopRecording(ctx)
+	rc.recordingLock.Unlock()
+
+	if err := rc.setAnnotation(ctx, statusAnnotation, "crash"); err != nil {
+		log.Printf("Warning: Failed to set crash status: %v", err)
+	}
+
+	rc.notify(ctx, Event{
+		Type:      EventCrashDetected,
+		PodName:   rc.config.PodName,
+		Namespace: rc.config.Namespace,
+	})
+
+	files, err := rc.findRecordingFiles()
+	if err != nil {
+		log.Printf("Error finding recording files after crash: %v", err)
+		return
+	}
+
+	// Recording filenames are "recording-<timestamp>.undo", so a
+	// lexicographic sort is also a chronological one.
+	sort.Strings(files)
+
+	retain := rc.config.CrashRetainCount
+	if retain > len(files) {
+		retain = len(files)
+	}
+
+	for _, file := range files[len(files)-retain:] {
+		marker := file + crashMarkerSuffix
+		if err := os.WriteFile(marker, nil, 0644); err != nil {
+			log.Printf("Warning: Failed to mark %s as a crash recording: %v", file, err)
+			continue
+		}
+		log.Printf("Marked %s as a crash recording", file)
+	}
+}
@@ -0,0 +1,150 @@
+// crd.go reconciles RecordingRequest custom resources (group undo.io/v1alpha1)
+// targeting this pod. It uses a dynamic/unstructured client rather than a
+// generated typed clientset, since this repo has no codegen tooling and a
+// single CRD doesn't warrant adding one.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingRequestGVR identifies the RecordingRequest custom resource.
+var recordingRequestGVR = schema.GroupVersionResource{
+	Group:    "undo.io",
+	Version:  "v1alpha1",
+	Resource: "recordingrequests",
+}
+
+// recordingRequestHandledAnnotation records the UID of a RecordingRequest
+// once a recording has been started for it, so a sidecar restart (crash
+// loop, rolling deploy, node drain) doesn't re-trigger a brand-new
+// recording for a request the cluster still happens to have lying around:
+// the informer's AddFunc fires for pre-existing objects at startup exactly
+// the same as for newly-created ones.
+const recordingRequestHandledAnnotation = "undo.io/recording-request-handled"
+
+// startRecordingRequestController watches RecordingRequest custom resources
+// in this pod's namespace and starts a recording for each one whose
+// spec.targetPod matches POD_NAME or is unset, applying any destination or recipient
+// override it specifies. It is additive to the undo.io/live-record
+// annotation and the cron scheduler: whichever fires first wins, same as
+// any other startRecording caller.
+func (rc *RecorderController) startRecordingRequestController(ctx context.Context, targetPID int) error {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		rc.dynamicClient,
+		informerResyncPeriod,
+		rc.config.Namespace,
+		nil,
+	)
+
+	informer := factory.ForResource(recordingRequestGVR).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			rc.reconcileRecordingRequest(ctx, obj, targetPID)
+		},
+	})
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("waiting for RecordingRequest informer cache to sync")
+	}
+
+	log.Println("RecordingRequest controller started")
+	return nil
+}
+
+// reconcileRecordingRequest starts a recording for a RecordingRequest that
+// targets this pod. It is best-effort: objects targeting another pod are
+// skipped silently, and malformed objects are logged and skipped rather
+// than treated as fatal, since a single bad
+// custom resource shouldn't take down the sidecar.
+func (rc *RecorderController) reconcileRecordingRequest(ctx context.Context, obj interface{}, targetPID int) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		log.Printf("RecordingRequest controller: unexpected object type %T", obj)
+		return
+	}
+
+	targetPod, _, _ := unstructured.NestedString(u.Object, "spec", "targetPod")
+	if targetPod != "" && targetPod != rc.config.PodName {
+		return
+	}
+
+	if u.GetAnnotations()[recordingRequestHandledAnnotation] == string(u.GetUID()) {
+		return
+	}
+
+	rc.recordingLock.Lock()
+	defer rc.recordingLock.Unlock()
+
+	if rc.recordingProcess != nil {
+		log.Printf("RecordingRequest %s: a recording is already in progress, ignoring", u.GetName())
+		return
+	}
+
+	duration := rc.config.AutoRecordDuration
+	if rawDuration, found, _ := unstructured.NestedString(u.Object, "spec", "duration"); found && rawDuration != "" {
+		parsed, err := time.ParseDuration(rawDuration)
+		if err != nil {
+			log.Printf("RecordingRequest %s: invalid spec.duration %q: %v", u.GetName(), rawDuration, err)
+			return
+		}
+		duration = parsed
+	}
+
+	override := &RecordingOverride{}
+	override.S3KeyPrefix, _, _ = unstructured.NestedString(u.Object, "spec", "s3KeyPrefix")
+	if recipients, found, _ := unstructured.NestedStringSlice(u.Object, "spec", "encryptionRecipients"); found {
+		override.PGPFingerprints = recipients
+	}
+
+	if pgpEncryptor, ok := rc.encryptor.(*PGPEncryptor); ok && len(override.PGPFingerprints) > 0 {
+		if _, err := pgpEncryptor.Subset(override.PGPFingerprints); err != nil {
+			log.Printf("RecordingRequest %s: invalid spec.encryptionRecipients %v: %v", u.GetName(), override.PGPFingerprints, err)
+			return
+		}
+	}
+
+	if err := rc.startRecording(ctx, targetPID, override); err != nil {
+		log.Printf("RecordingRequest %s: failed to start recording: %v", u.GetName(), err)
+		return
+	}
+	log.Printf("RecordingRequest %s: recording started for %s", u.GetName(), duration)
+
+	if err := rc.markRecordingRequestHandled(ctx, u); err != nil {
+		log.Printf("RecordingRequest %s: failed to record handled status: %v", u.GetName(), err)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(duration):
+			log.Printf("RecordingRequest %s: duration elapsed, stopping recording", u.GetName())
+			rc.recordingLock.Lock()
+			rc.stopRecording(ctx)
+			rc.recordingLock.Unlock()
+		}
+	}()
+}
+
+// markRecordingRequestHandled annotates a RecordingRequest with its own UID
+// once a recording has been started for it, so a later reconcile (e.g.
+// after a sidecar restart re-lists existing objects) can tell it's already
+// been acted on.
+func (rc *RecorderController) markRecordingRequestHandled(ctx context.Context, u *unstructured.Unstructured) error {
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{"%s":"%s"}}}`, recordingRequestHandledAnnotation, u.GetUID()))
+	_, err := rc.dynamicClient.Resource(recordingRequestGVR).Namespace(u.GetNamespace()).Patch(
+		ctx, u.GetName(), types.MergePatchType, patch, metav1.PatchOptions{},
+	)
+	return err
+}
@@ -0,0 +1,66 @@
+// cron.go implements scheduled, cron-driven auto-recording: periodically
+// recording the target process for a fixed duration without requiring the
+// undo.io/live-record annotation to be flipped by hand.
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// startAutoRecordScheduler starts a cron schedule that records targetPID for
+// AutoRecordDuration on every tick. It is a no-op if AUTO_RECORD_SCHEDULE is
+// not configured.
+func (rc *RecorderController) startAutoRecordScheduler(ctx context.Context, targetPID int) error {
+	if rc.config.AutoRecordSchedule == "" {
+		return nil
+	}
+
+	scheduler := cron.New()
+	_, err := scheduler.AddFunc(rc.config.AutoRecordSchedule, func() {
+		rc.runScheduledRecording(ctx, targetPID)
+	})
+	if err != nil {
+		return wrapErr("parsing AUTO_RECORD_SCHEDULE", err)
+	}
+
+	scheduler.Start()
+	go func() {
+		<-ctx.Done()
+		<-scheduler.Stop().Done()
+	}()
+
+	log.Printf("Auto-record scheduler started: schedule=%q duration=%s",
+		rc.config.AutoRecordSchedule, rc.config.AutoRecordDuration)
+	return nil
+}
+
+func (rc *RecorderController) runScheduledRecording(ctx context.Context, targetPID int) {
+	rc.recordingLock.Lock()
+	defer rc.recordingLock.Unlock()
+
+	if rc.recordingProcess != nil {
+		log.Println("Auto-record: skipping tick, a recording is already in progress")
+		return
+	}
+
+	if err := rc.startRecording(ctx, targetPID, nil); err != nil {
+		log.Printf("Auto-record: failed to start recording: %v", err)
+		return
+	}
+	log.Println("Auto-record: recording started on schedule")
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(rc.config.AutoRecordDuration):
+			log.Println("Auto-record: duration elapsed, stopping recording")
+			rc.recordingLock.Lock()
+			rc.stopRecording(ctx)
+			rc.recordingLock.Unlock()
+		}
+	}()
+}
@@ -0,0 +1,136 @@
+// encryption_pgp.go implements public-key encryption to one or more OpenPGP
+// recipients, whose armored public keys are mounted from a Kubernetes secret.
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// PGPEncryptor implements Encryptor by encrypting to every armored public
+// key found in a recipients directory (typically a mounted Kubernetes
+// secret), using the suffix ".undo.gpg".
+type PGPEncryptor struct {
+	recipients  []*openpgp.Entity
+	fingerprint string
+}
+
+func newPGPEncryptor(recipientsDir string) (*PGPEncryptor, error) {
+	entries, err := os.ReadDir(recipientsDir)
+	if err != nil {
+		return nil, wrapErr("reading PGP recipients directory", err)
+	}
+
+	var recipients []*openpgp.Entity
+	var fingerprints []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		keyFile, err := os.Open(filepath.Join(recipientsDir, entry.Name()))
+		if err != nil {
+			return nil, wrapErr("opening recipient key "+entry.Name(), err)
+		}
+
+		keyring, err := openpgp.ReadArmoredKeyRing(keyFile)
+		keyFile.Close()
+		if err != nil {
+			return nil, wrapErr("reading recipient key "+entry.Name(), err)
+		}
+
+		for _, entity := range keyring {
+			recipients = append(recipients, entity)
+			fingerprints = append(fingerprints, entity.PrimaryKey.KeyIdString())
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil, wrapErr("configuring PGP encryption", os.ErrNotExist)
+	}
+
+	return &PGPEncryptor{
+		recipients:  recipients,
+		fingerprint: strings.Join(fingerprints, ","),
+	}, nil
+}
+
+func (e *PGPEncryptor) Encrypt(ctx context.Context, src io.Reader) (io.Reader, error) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	go func() {
+		encWriter, err := openpgp.Encrypt(pipeWriter, e.recipients, nil, nil, nil)
+		if err != nil {
+			pipeWriter.CloseWithError(wrapErr("opening PGP encryption stream", err))
+			return
+		}
+
+		if _, err := io.Copy(encWriter, src); err != nil {
+			encWriter.Close()
+			pipeWriter.CloseWithError(wrapErr("encrypting recording", err))
+			return
+		}
+
+		if err := encWriter.Close(); err != nil {
+			pipeWriter.CloseWithError(wrapErr("finalizing PGP encryption stream", err))
+			return
+		}
+
+		pipeWriter.Close()
+	}()
+
+	return pipeReader, nil
+}
+
+// Subset returns a PGPEncryptor restricted to the recipients whose key ID
+// matches one of fingerprints, for use when a single RecordingRequest asks
+// to encrypt to fewer than all of the configured recipients. It never adds
+// recipients beyond those already mounted in the recipients directory, and
+// it errors rather than falling back to e if none of fingerprints match: a
+// recipient override that doesn't resolve must not silently broaden who can
+// decrypt the recording to every configured recipient.
+func (e *PGPEncryptor) Subset(fingerprints []string) (*PGPEncryptor, error) {
+	if len(fingerprints) == 0 {
+		return e, nil
+	}
+
+	wanted := make(map[string]bool, len(fingerprints))
+	for _, fp := range fingerprints {
+		wanted[fp] = true
+	}
+
+	var recipients []*openpgp.Entity
+	var matched []string
+	for _, entity := range e.recipients {
+		keyID := entity.PrimaryKey.KeyIdString()
+		if wanted[keyID] {
+			recipients = append(recipients, entity)
+			matched = append(matched, keyID)
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil, wrapErr("resolving PGP recipient override", os.ErrNotExist)
+	}
+
+	return &PGPEncryptor{
+		recipients:  recipients,
+		fingerprint: strings.Join(matched, ","),
+	}, nil
+}
+
+func (e *PGPEncryptor) KeySuffix() string {
+	return ".gpg"
+}
+
+func (e *PGPEncryptor) Metadata() map[string]string {
+	return map[string]string{
+		"encryption":             "openpgp",
+		"encryption_fingerprint": e.fingerprint,
+	}
+}
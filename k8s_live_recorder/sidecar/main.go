@@ -37,12 +37,27 @@ func main() {
 		log.Fatalf("Failed to initialize recorder controller: %v", err)
 	}
 
+	targetPID, err := findTargetProcess(cfg.AppProcessName)
+	if err != nil {
+		log.Fatalf("Failed to find target process: %v", err)
+	}
+
 	uploaderStarted := make(chan struct{})
 	controller.startUploaderLoop(ctx, uploaderStarted)
 	<-uploaderStarted
-	log.Println("S3 uploader started successfully")
+	log.Println("Uploader started successfully")
+
+	if err := controller.startAutoRecordScheduler(ctx, targetPID); err != nil {
+		log.Fatalf("Failed to start auto-record scheduler: %v", err)
+	}
+
+	controller.startRemoteRetentionLoop(ctx)
+
+	if cfg.AutoRecordOnCrash {
+		go controller.watchForTargetCrash(ctx, targetPID)
+	}
 
-	if err := controller.Run(ctx); err != nil && err != context.Canceled {
+	if err := controller.Run(ctx, targetPID); err != nil && err != context.Canceled {
 		log.Fatalf("Controller execution failed: %v", err)
 	}
 
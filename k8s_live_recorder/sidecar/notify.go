@@ -0,0 +1,199 @@
+// notify.go defines the Notifier abstraction used to announce recording
+// lifecycle events (start, stop, upload success/failure, crash detection)
+// through one or more channels, and the factory that builds the configured
+// set of notifiers from the application config.
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var defaultNotifyTemplatesFS embed.FS
+
+// Notification channel names, as used in the NOTIFY_CHANNELS config value.
+const (
+	notifyChannelSlack     = "slack"
+	notifyChannelWebhook   = "webhook"
+	notifyChannelEmail     = "email"
+	notifyChannelPagerDuty = "pagerduty"
+)
+
+// EventType identifies the kind of recording lifecycle event being
+// announced; it also names the template used to render it, e.g. "start.tmpl".
+type EventType string
+
+const (
+	EventRecordingStarted EventType = "start"
+	EventRecordingStopped EventType = "stop"
+	EventUploadSucceeded  EventType = "upload-success"
+	EventUploadFailed     EventType = "upload-failure"
+	EventCrashDetected    EventType = "crash-detected"
+)
+
+// Event carries the fields available to notification templates.
+type Event struct {
+	Type            EventType
+	PodName         string
+	Namespace       string
+	RecordingFile   string
+	SizeBytes       int64
+	S3URL           string
+	DurationSeconds float64
+	Error           string
+}
+
+// Notifier announces an Event through one channel (Slack, a generic
+// webhook, SMTP email, PagerDuty, ...).
+type Notifier interface {
+	// Name identifies the channel in logs.
+	Name() string
+
+	// Send renders and delivers event. Callers are expected to retry
+	// transient failures via retryWithBackoff.
+	Send(ctx context.Context, event Event) error
+}
+
+// newNotifiers builds a Notifier for every channel listed in
+// cfg.NotifyChannels, in order, all sharing one set of rendered templates.
+func newNotifiers(cfg *Config) ([]Notifier, error) {
+	if len(cfg.NotifyChannels) == 0 {
+		return nil, nil
+	}
+
+	templates, err := loadNotifyTemplates(cfg.NotifyTemplatesDir)
+	if err != nil {
+		return nil, wrapErr("loading notification templates", err)
+	}
+
+	notifiers := make([]Notifier, 0, len(cfg.NotifyChannels))
+	for _, channel := range cfg.NotifyChannels {
+		switch channel {
+		case notifyChannelSlack:
+			notifiers = append(notifiers, &slackNotifier{webhookURL: cfg.SlackWebhookURL, templates: templates})
+		case notifyChannelWebhook:
+			notifiers = append(notifiers, &webhookNotifier{url: cfg.NotifyWebhookURL, templates: templates})
+		case notifyChannelEmail:
+			notifiers = append(notifiers, newEmailNotifier(cfg, templates))
+		case notifyChannelPagerDuty:
+			notifiers = append(notifiers, &pagerDutyNotifier{routingKey: cfg.PagerDutyRoutingKey, templates: templates})
+		default:
+			return nil, &UnknownNotifyChannelError{Channel: channel}
+		}
+	}
+
+	return notifiers, nil
+}
+
+// notify fans event out to every configured channel in the background,
+// retrying each with exponential backoff; a flaky Slack webhook or SMTP
+// server never blocks the recording or upload loop that triggered it.
+func (rc *RecorderController) notify(ctx context.Context, event Event) {
+	for _, notifier := range rc.notifiers {
+		notifier := notifier
+		go func() {
+			err := retryWithBackoff(ctx, 3, 500*time.Millisecond, func() error {
+				return notifier.Send(ctx, event)
+			})
+			if err != nil {
+				log.Printf("Notification via %s failed after retries: %v", notifier.Name(), err)
+			}
+		}()
+	}
+}
+
+// retryWithBackoff calls fn until it succeeds, ctx is cancelled, or attempts
+// tries have been made, doubling the delay between tries starting at
+// initialDelay.
+func retryWithBackoff(ctx context.Context, attempts int, initialDelay time.Duration, fn func() error) error {
+	delay := initialDelay
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		if i == attempts-1 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
+}
+
+// loadNotifyTemplates parses one text/template per EventType, preferring a
+// file named "<event-type>.tmpl" in overrideDir (typically a mounted
+// ConfigMap) and falling back to the embedded default.
+func loadNotifyTemplates(overrideDir string) (map[EventType]*template.Template, error) {
+	eventTypes := []EventType{
+		EventRecordingStarted,
+		EventRecordingStopped,
+		EventUploadSucceeded,
+		EventUploadFailed,
+		EventCrashDetected,
+	}
+
+	templates := make(map[EventType]*template.Template, len(eventTypes))
+	for _, eventType := range eventTypes {
+		fileName := string(eventType) + ".tmpl"
+
+		raw, err := readNotifyTemplate(overrideDir, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		tmpl, err := template.New(fileName).Parse(string(raw))
+		if err != nil {
+			return nil, wrapErr("parsing template "+fileName, err)
+		}
+		templates[eventType] = tmpl
+	}
+
+	return templates, nil
+}
+
+func readNotifyTemplate(overrideDir, fileName string) ([]byte, error) {
+	if overrideDir != "" {
+		data, err := os.ReadFile(filepath.Join(overrideDir, fileName))
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, wrapErr("reading template override "+fileName, err)
+		}
+	}
+
+	data, err := defaultNotifyTemplatesFS.ReadFile("templates/" + fileName)
+	if err != nil {
+		return nil, wrapErr("reading default template "+fileName, err)
+	}
+	return data, nil
+}
+
+// renderEvent executes the template registered for event.Type against event.
+func renderEvent(templates map[EventType]*template.Template, event Event) (string, error) {
+	tmpl, ok := templates[event.Type]
+	if !ok {
+		return "", fmt.Errorf("no template registered for event type %s", event.Type)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", wrapErr("rendering notification template", err)
+	}
+	return buf.String(), nil
+}
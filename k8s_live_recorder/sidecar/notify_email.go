@@ -0,0 +1,55 @@
+// notify_email.go implements Notifier by sending the rendered event body as
+// a plain-text email over SMTP.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"text/template"
+)
+
+type emailNotifier struct {
+	host      string
+	port      string
+	auth      smtp.Auth
+	from      string
+	to        string
+	templates map[EventType]*template.Template
+}
+
+func newEmailNotifier(cfg *Config, templates map[EventType]*template.Template) *emailNotifier {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &emailNotifier{
+		host:      cfg.SMTPHost,
+		port:      cfg.SMTPPort,
+		auth:      auth,
+		from:      cfg.SMTPFrom,
+		to:        cfg.SMTPTo,
+		templates: templates,
+	}
+}
+
+func (n *emailNotifier) Name() string {
+	return "email"
+}
+
+func (n *emailNotifier) Send(ctx context.Context, event Event) error {
+	body, err := renderEvent(n.templates, event)
+	if err != nil {
+		return err
+	}
+
+	subject := fmt.Sprintf("[Undo LiveRecorder] %s on %s", event.Type, event.PodName)
+	msg := fmt.Sprintf("Subject: %s\r\nTo: %s\r\n\r\n%s", subject, n.to, body)
+
+	addr := fmt.Sprintf("%s:%s", n.host, n.port)
+	if err := smtp.SendMail(addr, n.auth, n.from, []string{n.to}, []byte(msg)); err != nil {
+		return wrapErr("sending email notification", err)
+	}
+	return nil
+}
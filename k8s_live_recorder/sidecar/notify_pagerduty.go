@@ -0,0 +1,77 @@
+// notify_pagerduty.go implements Notifier using the PagerDuty Events API v2,
+// triggering an incident for each event rather than just posting a message.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+type pagerDutyNotifier struct {
+	routingKey string
+	templates  map[EventType]*template.Template
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string                `json:"routing_key"`
+	EventAction string                `json:"event_action"`
+	Payload     pagerDutyEventPayload `json:"payload"`
+}
+
+type pagerDutyEventPayload struct {
+	Summary  string `json:"summary"`
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+func (n *pagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+func (n *pagerDutyNotifier) Send(ctx context.Context, event Event) error {
+	summary, err := renderEvent(n.templates, event)
+	if err != nil {
+		return err
+	}
+
+	severity := "info"
+	if event.Type == EventUploadFailed || event.Type == EventCrashDetected {
+		severity = "error"
+	}
+
+	body, err := json.Marshal(pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyEventPayload{
+			Summary:  summary,
+			Source:   event.PodName,
+			Severity: severity,
+		},
+	})
+	if err != nil {
+		return wrapErr("encoding PagerDuty payload", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pagerDutyEventsURL, bytes.NewReader(body))
+	if err != nil {
+		return wrapErr("building PagerDuty request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return wrapErr("sending PagerDuty notification", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("PagerDuty Events API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// notify_slack.go implements Notifier for Slack incoming webhooks.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+type slackNotifier struct {
+	webhookURL string
+	templates  map[EventType]*template.Template
+}
+
+func (n *slackNotifier) Name() string {
+	return "slack"
+}
+
+func (n *slackNotifier) Send(ctx context.Context, event Event) error {
+	text, err := renderEvent(n.templates, event)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return wrapErr("encoding Slack payload", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return wrapErr("building Slack request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return wrapErr("sending Slack notification", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Slack webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
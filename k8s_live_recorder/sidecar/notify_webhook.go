@@ -0,0 +1,46 @@
+// notify_webhook.go implements Notifier for a generic HTTP webhook, for
+// operators wiring notifications into something other than Slack,
+// PagerDuty, or email.
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+type webhookNotifier struct {
+	url       string
+	templates map[EventType]*template.Template
+}
+
+func (n *webhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *webhookNotifier) Send(ctx context.Context, event Event) error {
+	body, err := renderEvent(n.templates, event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader([]byte(body)))
+	if err != nil {
+		return wrapErr("building webhook request", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Undo-Event-Type", string(event.Type))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return wrapErr("sending webhook notification", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -17,6 +18,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -25,7 +27,6 @@ import (
 const (
 	liveRecordAnnotation = "undo.io/live-record" // annotation for start and stop
 	statusAnnotation     = "undo.io/status"      // annotation for status
-	pollInterval         = 5 * time.Second
 )
 
 // Status enum values
@@ -38,8 +39,13 @@ const (
 
 type RecorderController struct {
 	clientset        *kubernetes.Clientset
+	dynamicClient    dynamic.Interface
 	config           *Config
+	backends         []StorageBackend
+	encryptor        Encryptor
+	notifiers        []Notifier
 	recordingProcess *exec.Cmd
+	recordingFile    string // path of the .undo file recordingProcess is currently writing; guarded by recordingLock
 	recordingLock    sync.Mutex
 }
 
@@ -54,88 +60,98 @@ func newRecorderController(cfg *Config) (*RecorderController, error) {
 		return nil, wrapErr("creating Kubernetes clientset", err)
 	}
 
-	return &RecorderController{
-		clientset: clientset,
-		config:    cfg,
-	}, nil
-}
+	dynamicClient, err := dynamic.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, wrapErr("creating Kubernetes dynamic client", err)
+	}
 
-func (rc *RecorderController) Run(ctx context.Context, targetPID int) error {
-	log.Println("Starting recorder controller loop")
-	log.Println("Waiting for instruction...")
-	time.Sleep(5 * time.Second)
-
-	ticker := time.NewTicker(pollInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			rc.stopRecording()
-			// Clear status on shutdown
-			if err := rc.setAnnotation(ctx, statusAnnotation, string(StatusIdle)); err != nil {
-				log.Printf("Warning: Failed to clear status on shutdown: %v", err)
-			}
-			return ctx.Err()
-		case <-ticker.C:
-			if err := rc.checkAnnotation(ctx, targetPID); err != nil {
-				log.Printf("Error checking annotations: %v", err)
-			}
-		}
+	backends, err := newStorageBackends(cfg)
+	if err != nil {
+		return nil, wrapErr("initializing storage backends", err)
 	}
-}
 
-func (rc *RecorderController) checkAnnotation(ctx context.Context, targetPID int) error {
-	pod, err := rc.clientset.CoreV1().Pods(rc.config.Namespace).Get(
-		ctx, rc.config.PodName, metav1.GetOptions{})
+	encryptor, err := newEncryptor(cfg)
 	if err != nil {
-		return wrapErr("getting pod", err)
+		return nil, wrapErr("initializing encryption", err)
 	}
 
-	annotations := pod.GetAnnotations()
-	if annotations == nil {
-		return nil
+	notifiers, err := newNotifiers(cfg)
+	if err != nil {
+		return nil, wrapErr("initializing notifications", err)
 	}
 
-	if value, exists := annotations[liveRecordAnnotation]; exists {
+	return &RecorderController{
+		clientset:     clientset,
+		dynamicClient: dynamicClient,
+		config:        cfg,
+		backends:      backends,
+		encryptor:     encryptor,
+		notifiers:     notifiers,
+	}, nil
+}
 
-		if value == "" {
-            return nil
-        }
+// RecordingOverride carries per-recording destination and recipient
+// overrides requested by a RecordingRequest custom resource. It is nil for
+// recordings triggered by the undo.io/live-record annotation or the cron
+// scheduler, which always use the controller's static configuration.
+type RecordingOverride struct {
+	S3KeyPrefix     string
+	PGPFingerprints []string
+}
 
-		rc.recordingLock.Lock()
-		defer rc.recordingLock.Unlock()
+// overrideMarkerSuffix names the JSON sidecar file written alongside a
+// recording that was started with a RecordingOverride, mirroring the
+// crashMarkerSuffix convention used for crash-retained recordings. The
+// uploader reads it back to decide the remote key prefix and, for PGP
+// encryption, which subset of the configured recipients to encrypt to.
+const overrideMarkerSuffix = ".override.json"
+
+// applyAnnotations inspects a pod's current annotations and acts on a
+// pending undo.io/live-record start/stop command, if any. It is invoked by
+// the watch-driven work queue in watch.go rather than by polling the API
+// server directly.
+func (rc *RecorderController) applyAnnotations(ctx context.Context, annotations map[string]string, targetPID int) error {
+	if annotations == nil {
+		return nil
+	}
 
-		switch value {
-		case "start":
-			if rc.recordingProcess == nil {
-				if err := rc.startRecording(ctx, targetPID); err != nil {
-					return wrapErr("starting recording", err)
-				}
-				log.Println("Recording started successfully")
-			} else {
-				log.Println("Recording already in progress, ignoring start command")
-			}
+	value, exists := annotations[liveRecordAnnotation]
+	if !exists || value == "" {
+		return nil
+	}
+
+	rc.recordingLock.Lock()
+	defer rc.recordingLock.Unlock()
 
-		case "stop":
-			if rc.recordingProcess != nil {
-				rc.stopRecording()
-				log.Println("Recording stopped successfully")
-			} else {
-				log.Println("No recording in progress, ignoring stop command")
+	switch value {
+	case "start":
+		if rc.recordingProcess == nil {
+			if err := rc.startRecording(ctx, targetPID, nil); err != nil {
+				return wrapErr("starting recording", err)
 			}
+			log.Println("Recording started successfully")
+		} else {
+			log.Println("Recording already in progress, ignoring start command")
+		}
 
-		default:
-			log.Printf("Unknown value for %s annotation: %s", liveRecordAnnotation, value)
+	case "stop":
+		if rc.recordingProcess != nil {
+			rc.stopRecording(ctx)
+			log.Println("Recording stopped successfully")
+		} else {
+			log.Println("No recording in progress, ignoring stop command")
 		}
 
-		rc.clearAnnotation(ctx, liveRecordAnnotation)
+	default:
+		log.Printf("Unknown value for %s annotation: %s", liveRecordAnnotation, value)
 	}
 
+	rc.clearAnnotation(ctx, liveRecordAnnotation)
+
 	return nil
 }
 
-func (rc *RecorderController) startRecording(ctx context.Context, targetPID int) error {
+func (rc *RecorderController) startRecording(ctx context.Context, targetPID int, override *RecordingOverride) error {
 	// Set status to busy when recording starts
 	if err := rc.setAnnotation(ctx, statusAnnotation, string(StatusBusy)); err != nil {
 		log.Printf("Warning: Failed to set busy status: %v", err)
@@ -151,6 +167,16 @@ func (rc *RecorderController) startRecording(ctx context.Context, targetPID int)
 		return wrapErr("creating recordings directory", err)
 	}
 
+	if override != nil {
+		data, err := json.Marshal(override)
+		if err != nil {
+			return wrapErr("marshaling recording override", err)
+		}
+		if err := os.WriteFile(recordingFile+overrideMarkerSuffix, data, 0644); err != nil {
+			return wrapErr("writing recording override marker", err)
+		}
+	}
+
 	cmd := exec.CommandContext(
 		ctx,
 		liveRecordPath,
@@ -170,8 +196,16 @@ func (rc *RecorderController) startRecording(ctx context.Context, targetPID int)
 	}
 
 	rc.recordingProcess = cmd
+	rc.recordingFile = recordingFile
 	log.Printf("Recording started for PID %d to file %s", targetPID, recordingFile)
 
+	rc.notify(ctx, Event{
+		Type:          EventRecordingStarted,
+		PodName:       rc.config.PodName,
+		Namespace:     rc.config.Namespace,
+		RecordingFile: recordingFile,
+	})
+
 	go func() {
 		log.Println("Monitoring live-record process...")
 		if err := rc.recordingProcess.Wait(); err != nil {
@@ -183,12 +217,24 @@ func (rc *RecorderController) startRecording(ctx context.Context, targetPID int)
 		rc.recordingLock.Lock()
 		defer rc.recordingLock.Unlock()
 		rc.recordingProcess = nil
+		rc.recordingFile = ""
 	}()
 
 	return nil
 }
 
-func (rc *RecorderController) stopRecording() {
+// activeRecordingFile returns the path of the .undo file currently being
+// written by live-record, or "" if no recording is in progress. Callers
+// that enumerate recording files for upload or retention must exclude this
+// path: it's still open for writing and neither uploading nor pruning it is
+// safe until the process exits.
+func (rc *RecorderController) activeRecordingFile() string {
+	rc.recordingLock.Lock()
+	defer rc.recordingLock.Unlock()
+	return rc.recordingFile
+}
+
+func (rc *RecorderController) stopRecording(ctx context.Context) {
 	if rc.recordingProcess == nil || rc.recordingProcess.Process == nil {
 		return
 	}
@@ -198,6 +244,13 @@ func (rc *RecorderController) stopRecording() {
 	if err := rc.recordingProcess.Process.Signal(syscall.SIGINT); err != nil {
 		log.Printf("Error sending SIGINT to recording process: %v", err)
 	}
+
+	rc.notify(ctx, Event{
+		Type:          EventRecordingStopped,
+		PodName:       rc.config.PodName,
+		Namespace:     rc.config.Namespace,
+		RecordingFile: rc.recordingFile,
+	})
 }
 
 func (rc *RecorderController) clearAnnotation(ctx context.Context, key string) error {
@@ -0,0 +1,48 @@
+// remote_retention.go enforces a retention policy on every configured
+// storage backend, complementing the local disk retention in retention.go:
+// local retention bounds disk usage on this pod's volume, remote retention
+// bounds how long recordings are kept in the destination(s) they were
+// shipped to.
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// startRemoteRetentionLoop periodically prunes every configured storage
+// backend of recordings older than RemoteRetention. It is a no-op if
+// REMOTE_RETENTION is not configured.
+func (rc *RecorderController) startRemoteRetentionLoop(ctx context.Context) {
+	if rc.config.RemoteRetention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(rc.config.RemoteRetentionCheckInterval)
+	log.Printf("Remote retention loop started: retention=%s interval=%s",
+		rc.config.RemoteRetention, rc.config.RemoteRetentionCheckInterval)
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				rc.enforceRemoteRetention(ctx)
+			}
+		}
+	}()
+}
+
+// enforceRemoteRetention prunes every configured backend independently; a
+// failure on one backend is logged and doesn't stop the others from being
+// pruned.
+func (rc *RecorderController) enforceRemoteRetention(ctx context.Context) {
+	for _, backend := range rc.backends {
+		if err := backend.Prune(ctx, rc.config.RemoteRetention); err != nil {
+			log.Printf("Warning: Failed to prune backend %s: %v", backend.Name(), err)
+		}
+	}
+}
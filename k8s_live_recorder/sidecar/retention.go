@@ -0,0 +1,122 @@
+// retention.go enforces a local disk retention policy on the recordings
+// directory: if a stalled uploader (e.g. a persistent network outage) lets
+// .undo files pile up, the oldest ones are pruned before they fill the
+// volume, rather than letting the sidecar crash or the node run out of disk.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// localRetentionStatusAnnotation records the names of recordings dropped by
+// the local retention policy, so operators can see data loss at a glance.
+const localRetentionStatusAnnotation = "undo.io/local-retention"
+
+// enforceLocalRetention deletes the oldest recording files, and every
+// sidecar file written alongside them (crash/override markers, staged
+// ciphertext, upload-state.json), until the recordings directory satisfies
+// both MaxLocalRecordings and MaxLocalBytes. Either limit is optional; a
+// value of 0 disables that check.
+func (rc *RecorderController) enforceLocalRetention(ctx context.Context) {
+	if rc.config.MaxLocalRecordings <= 0 && rc.config.MaxLocalBytes <= 0 {
+		return
+	}
+
+	// findRecordingFiles already excludes the recording currently being
+	// written by live-record, so retention never prunes a file still open
+	// for writing.
+	files, err := rc.findRecordingFiles()
+	if err != nil {
+		log.Printf("Error finding recording files for retention check: %v", err)
+		return
+	}
+
+	// Recording filenames are "recording-<timestamp>.undo", so a
+	// lexicographic sort is also a chronological one, oldest first.
+	sort.Strings(files)
+
+	// Count the staged ciphertext (<file>.enc/.gpg) and per-backend
+	// upload-state.json sidecars written alongside a file mid-upload, not
+	// just the .undo file itself, so a stalled upload under encryption
+	// doesn't leave retention under-counting real disk usage.
+	sizes := make([]int64, len(files))
+	var totalBytes int64
+	for i, file := range files {
+		size := recordingAndSidecarsSize(file)
+		sizes[i] = size
+		totalBytes += size
+	}
+
+	var dropped []string
+	for len(files) > 0 {
+		overCount := rc.config.MaxLocalRecordings > 0 && len(files) > rc.config.MaxLocalRecordings
+		overBytes := rc.config.MaxLocalBytes > 0 && totalBytes > rc.config.MaxLocalBytes
+		if !overCount && !overBytes {
+			break
+		}
+
+		oldest := files[0]
+		if err := removeRecordingAndMarkers(oldest); err != nil {
+			log.Printf("Warning: Failed to prune local recording %s: %v", oldest, err)
+		} else {
+			log.Printf("Pruned local recording %s to satisfy local retention policy", oldest)
+			dropped = append(dropped, oldest)
+		}
+
+		totalBytes -= sizes[0]
+		files = files[1:]
+		sizes = sizes[1:]
+	}
+
+	if len(dropped) == 0 {
+		return
+	}
+
+	if err := rc.setAnnotation(ctx, localRetentionStatusAnnotation, strings.Join(dropped, ",")); err != nil {
+		log.Printf("Warning: Failed to record local retention status: %v", err)
+	}
+}
+
+// recordingAndSidecarsSize returns the on-disk size of file plus every
+// sidecar file written alongside it (crash/override markers, staged
+// ciphertext, per-backend upload-state.json), so size-based retention
+// reflects what the file is actually costing in disk space while an upload
+// is staged or in flight.
+func recordingAndSidecarsSize(file string) int64 {
+	var total int64
+	if info, err := os.Stat(file); err == nil {
+		total += info.Size()
+	}
+	for _, sidecar := range sidecarFiles(file) {
+		if info, err := os.Stat(sidecar); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
+// sidecarFiles lists every file sharing file's name as a dotted prefix:
+// crash/override markers, staged ciphertext (<file>.enc/.gpg), and
+// per-backend upload-state.json sidecars.
+func sidecarFiles(file string) []string {
+	matches, err := filepath.Glob(file + ".*")
+	if err != nil {
+		return nil
+	}
+	return matches
+}
+
+func removeRecordingAndMarkers(file string) error {
+	if err := os.Remove(file); err != nil {
+		return err
+	}
+	for _, sidecar := range sidecarFiles(file) {
+		os.Remove(sidecar)
+	}
+	return nil
+}
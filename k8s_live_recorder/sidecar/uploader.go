@@ -1,23 +1,27 @@
-// uploader.go defines the logic for uploading recording files to S3.
+// uploader.go defines the logic for uploading recording files to every
+// configured storage backend.
 package main
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 )
 
+// backendStatusAnnotation records per-backend upload outcomes, e.g.
+// "s3:ok,webdav:failed", so operators can see partial failures at a glance.
+const backendStatusAnnotation = "undo.io/backend-status"
+
 func (rc *RecorderController) startUploaderLoop(ctx context.Context, started chan struct{}) {
 	ticker := time.NewTicker(10 * time.Second)
 
-	log.Println("Starting S3 uploader loop...")
+	log.Println("Starting uploader loop...")
 	go func() {
 		close(started)
 		defer ticker.Stop()
@@ -34,6 +38,8 @@ func (rc *RecorderController) startUploaderLoop(ctx context.Context, started cha
 }
 
 func (rc *RecorderController) checkAndUploadRecordings(ctx context.Context) {
+	rc.enforceLocalRetention(ctx)
+
 	files, err := rc.findRecordingFiles()
 	if err != nil {
 		log.Printf("Error finding recording files: %v", err)
@@ -48,11 +54,9 @@ func (rc *RecorderController) checkAndUploadRecordings(ctx context.Context) {
 
 	successfulUploads := 0
 	for _, file := range files {
-		if err := rc.uploadFileToS3(file); err != nil {
-			log.Printf("Error uploading file %s: %v", file, err)
-			continue
+		if rc.uploadFileToBackends(ctx, file) {
+			successfulUploads++
 		}
-		successfulUploads++
 	}
 
 	if successfulUploads == len(files) {
@@ -65,50 +69,137 @@ func (rc *RecorderController) checkAndUploadRecordings(ctx context.Context) {
 	}
 }
 
+// findRecordingFiles lists every completed recording eligible for upload or
+// retention. The recording currently being written by live-record, if any,
+// is excluded: it's still open for writing, so uploading or pruning it
+// would race the live-record process.
 func (rc *RecorderController) findRecordingFiles() ([]string, error) {
 	entries, err := os.ReadDir(recordingsDir)
 	if err != nil {
 		return nil, wrapErr("reading recordings directory", err)
 	}
 
+	active := rc.activeRecordingFile()
+
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".undo") {
-			files = append(files, filepath.Join(recordingsDir, entry.Name()))
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".undo") {
+			continue
+		}
+		path := filepath.Join(recordingsDir, entry.Name())
+		if path == active {
+			continue
 		}
+		files = append(files, path)
 	}
 
 	return files, nil
 }
 
-func (rc *RecorderController) uploadFileToS3(filePath string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return wrapErr("opening file for upload", err)
+// uploadFileToBackends encrypts the file (if encryption is configured),
+// fans the result out to every configured backend in parallel, reports a
+// per-backend status annotation, and deletes the local file only once
+// every backend has confirmed success.
+func (rc *RecorderController) uploadFileToBackends(ctx context.Context, filePath string) bool {
+	uploadStart := time.Now()
+	fileName := filepath.Base(filePath)
+
+	keyPrefix := rc.config.S3KeyPrefix
+	isCrashRecording := false
+	if _, err := os.Stat(filePath + crashMarkerSuffix); err == nil {
+		keyPrefix = rc.config.CrashS3KeyPrefix
+		isCrashRecording = true
 	}
-	defer file.Close()
 
-	fileName := filepath.Base(filePath)
-	s3Key := filepath.Join(rc.config.S3KeyPrefix, fileName)
+	encryptor := rc.encryptor
+	overridePath := filePath + overrideMarkerSuffix
+	hasOverride := false
+	if data, err := os.ReadFile(overridePath); err == nil {
+		hasOverride = true
+		var override RecordingOverride
+		if err := json.Unmarshal(data, &override); err != nil {
+			log.Printf("Warning: Failed to parse recording override for %s: %v", filePath, err)
+		} else {
+			if override.S3KeyPrefix != "" {
+				keyPrefix = override.S3KeyPrefix
+			}
+			if pgpEncryptor, ok := rc.encryptor.(*PGPEncryptor); ok && len(override.PGPFingerprints) > 0 {
+				subset, err := pgpEncryptor.Subset(override.PGPFingerprints)
+				if err != nil {
+					log.Printf("Error applying PGP recipient override for %s: %v", filePath, err)
+					return false
+				}
+				encryptor = subset
+			}
+		}
+	}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(rc.config.S3Region),
-	})
+	remoteKey := filepath.Join(keyPrefix, fileName) + encryptor.KeySuffix()
+
+	stagedPath, cleanupStaged, err := rc.stageForUpload(ctx, filePath, encryptor)
 	if err != nil {
-		return wrapErr("creating AWS session", err)
+		log.Printf("Error encrypting %s: %v", filePath, err)
+		return false
 	}
 
-	uploader := s3manager.NewUploader(sess)
-	result, err := uploader.Upload(&s3manager.UploadInput{
-		Bucket: aws.String(rc.config.S3BucketName),
-		Key:    aws.String(s3Key),
-		Body:   file,
-	})
-	if err != nil {
-		return wrapErr("uploading file to S3", err)
+	metadata := encryptor.Metadata()
+
+	results := make([]string, len(rc.backends))
+	var wg sync.WaitGroup
+	for i, backend := range rc.backends {
+		wg.Add(1)
+		go func(i int, backend StorageBackend) {
+			defer wg.Done()
+			if err := backend.Upload(ctx, stagedPath, remoteKey, metadata); err != nil {
+				log.Printf("Error uploading %s to backend %s: %v", filePath, backend.Name(), err)
+				results[i] = backend.Name() + ":failed"
+				return
+			}
+			log.Printf("Successfully uploaded %s to backend %s", filePath, backend.Name())
+			results[i] = backend.Name() + ":ok"
+		}(i, backend)
+	}
+	wg.Wait()
+
+	allSucceeded := true
+	for _, result := range results {
+		if !strings.HasSuffix(result, ":ok") {
+			allSucceeded = false
+		}
+	}
+
+	if err := rc.setAnnotation(ctx, backendStatusAnnotation, strings.Join(results, ",")); err != nil {
+		log.Printf("Warning: Failed to record backend status: %v", err)
 	}
 
-	log.Printf("Successfully uploaded file to %s", result.Location)
+	var sizeBytes int64
+	if info, err := os.Stat(filePath); err == nil {
+		sizeBytes = info.Size()
+	}
+
+	if !allSucceeded {
+		rc.notify(ctx, Event{
+			Type:          EventUploadFailed,
+			PodName:       rc.config.PodName,
+			Namespace:     rc.config.Namespace,
+			RecordingFile: fileName,
+			SizeBytes:     sizeBytes,
+			Error:         strings.Join(results, ","),
+		})
+		return false
+	}
+
+	rc.notify(ctx, Event{
+		Type:            EventUploadSucceeded,
+		PodName:         rc.config.PodName,
+		Namespace:       rc.config.Namespace,
+		RecordingFile:   fileName,
+		SizeBytes:       sizeBytes,
+		S3URL:           remoteKey,
+		DurationSeconds: time.Since(uploadStart).Seconds(),
+	})
+
+	cleanupStaged()
 
 	if err := os.Remove(filePath); err != nil {
 		log.Printf("Warning: Failed to delete local file %s: %v", filePath, err)
@@ -116,5 +207,63 @@ func (rc *RecorderController) uploadFileToS3(filePath string) error {
 		log.Printf("Deleted local file %s", filePath)
 	}
 
-	return nil
+	if isCrashRecording {
+		os.Remove(filePath + crashMarkerSuffix)
+	}
+
+	if hasOverride {
+		os.Remove(overridePath)
+	}
+
+	return true
+}
+
+// stageForUpload runs encryptor over filePath, if any, and returns the path
+// backends should read from plus a cleanup func that removes any staged
+// ciphertext file. When encryption is disabled, the original path is
+// returned unchanged and cleanup is a no-op.
+func (rc *RecorderController) stageForUpload(ctx context.Context, filePath string, encryptor Encryptor) (string, func(), error) {
+	suffix := encryptor.KeySuffix()
+	if suffix == "" {
+		return filePath, func() {}, nil
+	}
+
+	stagedPath := filePath + suffix
+	cleanup := func() { os.Remove(stagedPath) }
+
+	// A staged file already on disk means a previous upload attempt got as
+	// far as staging (and possibly partially uploaded parts of it, tracked
+	// in the backend's upload-state.json). Encryptor.Encrypt generates a
+	// fresh salt/nonce/session key on every call, so re-encrypting here
+	// would silently produce different ciphertext than what was already
+	// partially uploaded, corrupting the resumed object. The plaintext
+	// recording file is never modified once written, so it's safe to reuse
+	// the existing ciphertext as-is.
+	if info, err := os.Stat(stagedPath); err == nil && info.Size() > 0 {
+		return stagedPath, cleanup, nil
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return "", nil, wrapErr("opening file for encryption", err)
+	}
+	defer src.Close()
+
+	encrypted, err := encryptor.Encrypt(ctx, src)
+	if err != nil {
+		return "", nil, wrapErr("starting encryption", err)
+	}
+
+	staged, err := os.OpenFile(stagedPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", nil, wrapErr("creating staged ciphertext file", err)
+	}
+	defer staged.Close()
+
+	if _, err := io.Copy(staged, encrypted); err != nil {
+		os.Remove(stagedPath)
+		return "", nil, wrapErr("writing staged ciphertext file", err)
+	}
+
+	return stagedPath, cleanup, nil
 }
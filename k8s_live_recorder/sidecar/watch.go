@@ -0,0 +1,118 @@
+// watch.go replaces annotation polling with a Kubernetes watch. A
+// SharedInformerFactory scoped to this single pod (via a field selector on
+// metadata.name) delivers annotation changes within milliseconds instead of
+// on a fixed 5-second poll, and each change is dispatched through a
+// RateLimitingInterface work queue so a transient API error just retries
+// with backoff instead of being dropped on the floor.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const informerResyncPeriod = 10 * time.Minute
+
+// Run watches this pod for undo.io/live-record annotation transitions and
+// RecordingRequest custom resources targeting it, dispatching start/stop
+// commands to targetPID until ctx is cancelled.
+func (rc *RecorderController) Run(ctx context.Context, targetPID int) error {
+	log.Println("Starting recorder controller watch loop")
+
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		rc.clientset,
+		informerResyncPeriod,
+		informers.WithNamespace(rc.config.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", rc.config.PodName).String()
+		}),
+	)
+
+	podInformer := factory.Core().V1().Pods().Informer()
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { queue.Add(struct{}{}) },
+		UpdateFunc: func(oldObj, newObj interface{}) { queue.Add(struct{}{}) },
+	})
+
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), podInformer.HasSynced) {
+		return fmt.Errorf("waiting for pod informer cache to sync")
+	}
+
+	if err := rc.startRecordingRequestController(ctx, targetPID); err != nil {
+		return wrapErr("starting RecordingRequest controller", err)
+	}
+
+	go rc.processAnnotationQueue(ctx, queue, podInformer, targetPID)
+
+	<-ctx.Done()
+	rc.recordingLock.Lock()
+	rc.stopRecording(context.Background())
+	rc.recordingLock.Unlock()
+	if err := rc.setAnnotation(context.Background(), statusAnnotation, string(StatusIdle)); err != nil {
+		log.Printf("Warning: Failed to clear status on shutdown: %v", err)
+	}
+	return ctx.Err()
+}
+
+// processAnnotationQueue drains work items pushed by the pod informer,
+// re-reading the pod's annotations from the informer's local cache (not the
+// API server) and applying any pending start/stop command. Failures are
+// retried with the queue's exponential backoff rather than handled inline.
+func (rc *RecorderController) processAnnotationQueue(
+	ctx context.Context,
+	queue workqueue.RateLimitingInterface,
+	podInformer cache.SharedIndexInformer,
+	targetPID int,
+) {
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return
+		}
+
+		if err := rc.handlePodUpdate(ctx, podInformer, targetPID); err != nil {
+			log.Printf("Error handling pod update, will retry: %v", err)
+			queue.AddRateLimited(item)
+		} else {
+			queue.Forget(item)
+		}
+		queue.Done(item)
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (rc *RecorderController) handlePodUpdate(ctx context.Context, podInformer cache.SharedIndexInformer, targetPID int) error {
+	key := rc.config.Namespace + "/" + rc.config.PodName
+	obj, exists, err := podInformer.GetStore().GetByKey(key)
+	if err != nil {
+		return wrapErr("getting pod from informer cache", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("unexpected informer object type %T", obj)
+	}
+
+	return rc.applyAnnotations(ctx, pod.GetAnnotations(), targetPID)
+}